@@ -2,20 +2,34 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/giulianopz/go-gsst/pkg/audio/flacenc"
+	"github.com/giulianopz/go-gsst/pkg/audio/record"
+	"github.com/giulianopz/go-gsst/pkg/audio/transcode"
 	"github.com/giulianopz/go-gsst/pkg/client"
 	"github.com/giulianopz/go-gsst/pkg/logger"
+	"github.com/giulianopz/go-gsst/pkg/mic"
 	"github.com/giulianopz/go-gsst/pkg/opts"
 	"github.com/giulianopz/go-gsst/pkg/str"
+	"github.com/giulianopz/go-gsst/pkg/vad"
 	goflac "github.com/go-flac/go-flac"
 )
 
+// streamer is implemented by every backend client.Stream can dispatch to.
+type streamer interface {
+	Stream(ctx context.Context, r io.Reader, sampleRate int, o *opts.Options, handler client.ResultHandler) error
+}
+
 const usage = `Usage:
     gstt [OPTION]... -key $KEY -output [pb|json]
     gstt [OPTION]... -key $KEY --interim -continuous -output [pb|json]
@@ -31,19 +45,39 @@ Options:
 	--max-alts, how many possible transcriptions do you want
 	--pfilter, profanity filter ('0'=off, '1'=medium, '2'=strict)
 	--user-agent, user-agent for spoofing
+	--backend, speech recognition backend to use ('chrome' for the unofficial endpoint or 'cloud-v2' for Google Cloud Speech-to-Text v2)
+	--project, GCP project id (required for --backend cloud-v2)
+	--location, GCP location of the recognizer, i.e. 'global' (required for --backend cloud-v2)
+	--recognizer, full recognizer resource name; overrides --project/--location when set
+	--mic, capture audio from the default input device instead of reading raw bytes from stdin
+	--input-format, treat --file as this format instead of probing it ('flac', 'wav', 'mp3', 'ogg', 'opus', 'mp4'); it only decides whether the file is streamed as FLAC or transcoded, the transcoder still probes the file itself
+	--target-sample-rate, resample --file to this rate when transcoding (default: 16000, or the source's rate for a WAV file decoded without ffmpeg)
+	--channels, downmix/upmix --file to this channel count when transcoding (default: 1, or the source's channel count for a WAV file decoded without ffmpeg)
+	--record, also save the captured --mic audio to this path, as WAV, FLAC or MP3 depending on its extension (MP3 requires building with -tags mp3 and libmp3lame installed)
+	--vad, segment --mic audio into utterances with voice activity detection and transparently resume the upstream connection as long sessions approach the backend's duration cap
 `
 
 var (
-	verbose    bool
-	filePath   string
-	apiKey     string
-	output     string
-	language   string
-	continuous bool
-	interim    bool
-	maxAlts    string
-	pFilter    string
-	userAgent  string
+	verbose          bool
+	filePath         string
+	apiKey           string
+	output           string
+	language         string
+	continuous       bool
+	interim          bool
+	maxAlts          string
+	pFilter          string
+	userAgent        string
+	backend          string
+	project          string
+	location         string
+	recognizer       string
+	useMic           bool
+	inputFormat      string
+	targetSampleRate int
+	channels         int
+	recordPath       string
+	useVAD           bool
 )
 
 func main() {
@@ -58,6 +92,16 @@ func main() {
 	flag.StringVar(&maxAlts, "max-alts", "1", "how many possible transcriptions do you want")
 	flag.StringVar(&pFilter, "pfilter", "2", "profanity filter ('0'=off, '1'=medium, '2'=strict)")
 	flag.StringVar(&userAgent, "user-agent", opts.DefaultUserAgent, "user-agent for spoofing (default 'Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36')")
+	flag.StringVar(&backend, "backend", string(opts.Chrome), "speech recognition backend to use ('chrome' or 'cloud-v2')")
+	flag.StringVar(&project, "project", "", "GCP project id (required for --backend cloud-v2)")
+	flag.StringVar(&location, "location", "global", "GCP location of the recognizer (required for --backend cloud-v2)")
+	flag.StringVar(&recognizer, "recognizer", "", "full recognizer resource name; overrides --project/--location when set")
+	flag.BoolVar(&useMic, "mic", false, "capture audio from the default input device instead of reading raw bytes from stdin")
+	flag.StringVar(&inputFormat, "input-format", "", "treat --file as this format instead of probing it ('flac', 'wav', 'mp3', 'ogg', 'opus', 'mp4'); it only decides whether the file is streamed as FLAC or transcoded")
+	flag.IntVar(&targetSampleRate, "target-sample-rate", 0, "resample --file to this rate when transcoding (default: 16000, or the source's rate for a WAV file decoded without ffmpeg)")
+	flag.IntVar(&channels, "channels", 0, "downmix/upmix --file to this channel count when transcoding (default: 1, or the source's channel count for a WAV file decoded without ffmpeg)")
+	flag.StringVar(&recordPath, "record", "", "also save the captured --mic audio to this path, as WAV, FLAC or MP3 depending on its extension")
+	flag.BoolVar(&useVAD, "vad", false, "segment --mic audio into utterances with voice activity detection and transparently resume the upstream connection as long sessions approach the backend's duration cap")
 	flag.Usage = func() { fmt.Print(usage) }
 	flag.Parse()
 
@@ -66,27 +110,107 @@ func main() {
 	}
 
 	var (
-		c       = client.New()
+		ctx     = context.Background()
+		c       = newStreamer()
 		options = fromFlags()
+		handler = newHandler(options)
 	)
 
 	if filePath != "" { // transcribe from file
 
-		f, err := goflac.ParseFile(filePath)
-		if err != nil {
-			logger.Error("cannot parse file", "err", err)
-			os.Exit(1)
+		format := transcode.Format(inputFormat)
+		if format == "" {
+			detected, err := transcode.Detect(filePath)
+			if err != nil {
+				logger.Error("cannot detect file format", "err", err)
+				os.Exit(1)
+			}
+			format = detected
+		}
+
+		if format == transcode.FLAC {
+			f, err := goflac.ParseFile(filePath)
+			if err != nil {
+				logger.Error("cannot parse file", "err", err)
+				os.Exit(1)
+			}
+			data, err := f.GetStreamInfo()
+			if err != nil {
+				logger.Error("cannot get file info", "err", err)
+				os.Exit(1)
+			}
+			logger.Info("done parsing file", "sample rate", data.SampleRate)
+
+			if err := c.Stream(ctx, bytes.NewBuffer(f.Marshal()), data.SampleRate, options, handler); err != nil {
+				logger.Error("cannot stream file", "err", err)
+				os.Exit(1)
+			}
+
+		} else {
+			r, sampleRate, err := transcode.ToFLAC(filePath, targetSampleRate, channels)
+			if err != nil {
+				logger.Error("cannot transcode file", "err", err, "format", format)
+				os.Exit(1)
+			}
+			defer r.Close()
+
+			logger.Info("done transcoding file", "format", format, "sample rate", sampleRate)
+
+			if err := c.Stream(ctx, r, sampleRate, options, handler); err != nil {
+				logger.Error("cannot stream file", "err", err)
+				os.Exit(1)
+			}
 		}
-		data, err := f.GetStreamInfo()
+
+	} else if useMic { // capture and encode from the default input device
+
+		capture, err := mic.Open(opts.DefaultSampleRate, 1)
 		if err != nil {
-			logger.Error("cannot get file info", "err", err)
+			logger.Error("cannot open microphone", "err", err)
 			os.Exit(1)
 		}
-		logger.Info("done parsing file", "sample rate", data.SampleRate)
+		defer capture.Close()
 
-		c.Stream(bytes.NewBuffer(f.Marshal()), data.SampleRate, options)
+		// On SIGINT/SIGTERM, close the capture instead of letting the
+		// default handler kill the process outright, so EOF propagates
+		// down the pipe and record.Tee/the FLAC encoder get to finalize
+		// the recording instead of leaving it truncated.
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sig
+			logger.Info("received interrupt, finishing up")
+			capture.Close()
+		}()
 
-	} else { // transcribe from microphone input
+		var pcm io.Reader = capture
+		if options.RecordPath != "" {
+			pcm, err = record.Tee(capture, options.RecordPath, record.Format(options.RecordFormat), opts.DefaultSampleRate, 1)
+			if err != nil {
+				logger.Error("cannot start recording", "err", err)
+				os.Exit(1)
+			}
+		}
+
+		if options.VADEnabled {
+			if err := streamWithVAD(ctx, c, pcm, options, handler); err != nil {
+				logger.Error("cannot stream microphone input", "err", err)
+				os.Exit(1)
+			}
+		} else {
+			encoder, err := flacenc.NewEncoder(pcm, opts.DefaultSampleRate, 1)
+			if err != nil {
+				logger.Error("cannot start flac encoder", "err", err)
+				os.Exit(1)
+			}
+
+			if err := c.Stream(ctx, encoder, opts.DefaultSampleRate, options, handler); err != nil {
+				logger.Error("cannot stream microphone input", "err", err)
+				os.Exit(1)
+			}
+		}
+
+	} else { // transcribe raw bytes piped into stdin
 
 		// 1kB chunk size
 		bs := make([]byte, 1024)
@@ -97,7 +221,9 @@ func main() {
 			defer pr.Close()
 			defer pw.Close()
 
-			c.Stream(pr, opts.DefaultSampleRate, options)
+			if err := c.Stream(ctx, pr, opts.DefaultSampleRate, options, handler); err != nil {
+				logger.Error("cannot stream microphone input", "err", err)
+			}
 		}()
 
 		for {
@@ -120,6 +246,85 @@ func main() {
 	}
 }
 
+// newStreamer picks the backend client.Stream implementation based on the
+// --backend flag.
+func newStreamer() streamer {
+	if opts.BackendKind(backend) != opts.CloudV2 {
+		return client.New()
+	}
+
+	if recognizer == "" && project == "" {
+		logger.Error("--backend cloud-v2 requires --project or --recognizer")
+		os.Exit(1)
+	}
+
+	c, err := client.NewCloudV2(context.Background(), project, location, recognizer)
+	if err != nil {
+		logger.Error("cannot create cloud-v2 client", "err", err)
+		os.Exit(1)
+	}
+	return c
+}
+
+// vadFrameDuration is the frame size vad.Detector and flacenc both
+// encode in lockstep with.
+const vadFrameDuration = 20 * time.Millisecond
+
+// streamWithVAD segments pcm into utterances using voice-activity
+// detection, streaming each one to completion on its own upstream
+// connection so a long session never hits a backend's per-stream
+// duration cap. Each connection is allowed to finish normally once its
+// utterance ends, so the real transcription the backend sends back is
+// never discarded; the tail of a cut utterance is replayed at the start
+// of the next connection so no word spanning the cut is lost.
+func streamWithVAD(ctx context.Context, c streamer, pcm io.Reader, o *opts.Options, handler client.ResultHandler) error {
+
+	detector := vad.New(o.VADSensitivity, opts.DefaultSampleRate)
+	frameSize := opts.DefaultSampleRate / 1000 * int(vadFrameDuration/time.Millisecond) * 2 // 16-bit mono PCM
+
+	var resumeTail []byte
+	for {
+		seg := vad.NewSegmenter(detector, vadFrameDuration, o.VADMinSilence, o.VADMaxUtterance)
+		tap := vad.NewTap(pcm, seg, frameSize)
+
+		var r io.Reader = tap
+		if len(resumeTail) > 0 {
+			r = io.MultiReader(bytes.NewReader(resumeTail), tap)
+		}
+
+		encoder, err := flacenc.NewEncoder(r, opts.DefaultSampleRate, 1)
+		if err != nil {
+			return fmt.Errorf("cannot start flac encoder: %w", err)
+		}
+
+		if err := c.Stream(ctx, encoder, opts.DefaultSampleRate, o, handler); err != nil {
+			return err
+		}
+
+		switch tap.Boundary {
+		case vad.Utterance:
+			handler.OnEndpoint(client.VoiceActivityEvent{Type: "utterance_end"})
+			resumeTail = nil
+		case vad.MaxDuration:
+			handler.OnEndpoint(client.VoiceActivityEvent{Type: "max_duration"})
+			resumeTail = tap.Resume()
+		default:
+			// pcm itself reached EOF (e.g. mic closed) without crossing a
+			// VAD boundary; nothing left to stream.
+			return nil
+		}
+	}
+}
+
+// newHandler picks the ResultHandler matching --output, preserving the
+// CLI's historical stdout format.
+func newHandler(o *opts.Options) client.ResultHandler {
+	if o.Output == opts.Text {
+		return client.StdoutJSON{}
+	}
+	return client.StdoutProtobuf{}
+}
+
 func fromFlags() *opts.Options {
 
 	options := make([]opts.Option, 0)
@@ -164,6 +369,31 @@ func fromFlags() *opts.Options {
 		options = append(options, opts.ProfanityFilter(num))
 	}
 	options = append(options, opts.UserAgent(str.GetOrDefault(userAgent, opts.DefaultUserAgent)))
+	options = append(options, opts.Backend(opts.BackendKind(backend)))
+	if project != "" {
+		options = append(options, opts.Project(project))
+	}
+	if location != "" {
+		options = append(options, opts.Location(location))
+	}
+	if recognizer != "" {
+		options = append(options, opts.Recognizer(recognizer))
+	}
+	if inputFormat != "" {
+		options = append(options, opts.InputFormat(inputFormat))
+	}
+	if targetSampleRate != 0 {
+		options = append(options, opts.TargetSampleRate(targetSampleRate))
+	}
+	if channels != 0 {
+		options = append(options, opts.Channels(channels))
+	}
+	if recordPath != "" {
+		options = append(options, opts.RecordTo(recordPath, string(record.FormatFromPath(recordPath))))
+	}
+	if useVAD {
+		options = append(options, opts.VAD(opts.DefaultVADSensitivity, opts.DefaultVADMinSilence, opts.DefaultVADMaxUtterance))
+	}
 
 	return opts.Apply(options...)
 }
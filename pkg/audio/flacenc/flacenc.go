@@ -0,0 +1,121 @@
+// Package flacenc encodes a stream of raw PCM audio into FLAC in real
+// time, exposing the result as an io.Reader so it can be wired straight
+// into client.Stream.
+package flacenc
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+const bitsPerSample = 16
+
+// blockSize is the number of samples per channel encoded into each FLAC
+// frame. 320 samples at 16kHz is a 20ms block, matching the frame size
+// the mic and VAD packages already work with.
+const blockSize = 320
+
+// Encoder reads raw little-endian 16-bit PCM from src and re-exposes it
+// as a FLAC bitstream.
+type Encoder struct {
+	pr *io.PipeReader
+}
+
+// NewEncoder starts encoding PCM read from src (sampleRate, channels) to
+// FLAC in a background goroutine and returns an io.Reader for the result.
+func NewEncoder(src io.Reader, sampleRate, channels int) (*Encoder, error) {
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+
+		// flac.NewEncoder writes the FLAC signature and StreamInfo block
+		// straight into pw as part of construction, so it must run after
+		// the pipe is handed back to the caller; io.Pipe is unbuffered
+		// and there's no reader until then.
+		enc, err := flac.NewEncoder(pw, &meta.StreamInfo{
+			BlockSizeMin:  blockSize,
+			BlockSizeMax:  blockSize,
+			SampleRate:    uint32(sampleRate),
+			NChannels:     uint8(channels),
+			BitsPerSample: bitsPerSample,
+		})
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		defer enc.Close()
+
+		if err := encodeLoop(enc, src, channels); err != nil {
+			logger.Error("cannot encode flac frame", "err", err)
+			pw.CloseWithError(err)
+		}
+	}()
+
+	return &Encoder{pr: pr}, nil
+}
+
+func encodeLoop(enc *flac.Encoder, src io.Reader, channels int) error {
+	raw := make([]byte, blockSize*channels*(bitsPerSample/8))
+
+	for {
+		n, err := io.ReadFull(src, raw)
+		if n > 0 {
+			if werr := enc.WriteFrame(toFrame(raw[:n], channels)); werr != nil {
+				return werr
+			}
+		}
+
+		switch err {
+		case nil:
+			continue
+		case io.EOF, io.ErrUnexpectedEOF:
+			// io.ReadFull reports a short final read as ErrUnexpectedEOF;
+			// that's a clean end of stream here, not a real read error.
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+func toFrame(raw []byte, channels int) *frame.Frame {
+	nSamples := len(raw) / 2 / channels
+
+	subframes := make([]*frame.Subframe, channels)
+	for ch := range subframes {
+		subframes[ch] = &frame.Subframe{
+			SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+			Samples:   make([]int32, nSamples),
+			NSamples:  nSamples,
+		}
+	}
+
+	for i := 0; i < nSamples; i++ {
+		for ch := 0; ch < channels; ch++ {
+			off := (i*channels + ch) * 2
+			subframes[ch].Samples[i] = int32(int16(binary.LittleEndian.Uint16(raw[off : off+2])))
+		}
+	}
+
+	return &frame.Frame{
+		Header: frame.Header{
+			BlockSize:     uint16(nSamples),
+			Channels:      frame.Channels(channels - 1),
+			BitsPerSample: bitsPerSample,
+		},
+		Subframes: subframes,
+	}
+}
+
+// Read implements io.Reader over the encoded FLAC bitstream.
+func (e *Encoder) Read(p []byte) (int, error) {
+	return e.pr.Read(p)
+}
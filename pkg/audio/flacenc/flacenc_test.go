@@ -0,0 +1,90 @@
+package flacenc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mewkiz/flac"
+)
+
+// pcmOf packs samples as little-endian 16-bit PCM, one sample per channel
+// per frame (mono here).
+func pcmOf(samples ...int16) []byte {
+	buf := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(buf[2*i:2*i+2], uint16(s))
+	}
+	return buf
+}
+
+// TestNewEncoderRoundTrip guards against the pipe deadlock between
+// io.Pipe and flac.NewEncoder: if construction ever moves back to
+// running before a reader exists, this test hangs instead of failing
+// cleanly, so it's run with its own timeout.
+func TestNewEncoderRoundTrip(t *testing.T) {
+	nSamples := blockSize + blockSize/2 // forces a short final block
+	samples := make([]int16, nSamples)
+	for i := range samples {
+		samples[i] = int16(i)
+	}
+	src := bytes.NewReader(pcmOf(samples...))
+
+	encoded := make(chan []byte, 1)
+	newErrs := make(chan error, 1)
+	errs := make(chan error, 1)
+	go func() {
+		// NewEncoder runs inside the timed goroutine so a deadlock in
+		// construction is caught by the timeout below too.
+		enc, err := NewEncoder(src, 16000, 1)
+		if err != nil {
+			newErrs <- err
+			return
+		}
+		b, err := io.ReadAll(enc)
+		if err != nil {
+			errs <- err
+			return
+		}
+		encoded <- b
+	}()
+
+	select {
+	case err := <-newErrs:
+		t.Fatalf("NewEncoder() error = %v", err)
+	case err := <-errs:
+		t.Fatalf("reading encoded stream: %v", err)
+	case b := <-encoded:
+		stream, err := flac.New(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("cannot parse encoded flac stream: %v", err)
+		}
+
+		var got []int16
+		for {
+			f, err := stream.ParseNext()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("ParseNext() error = %v", err)
+			}
+			for _, s := range f.Subframes[0].Samples {
+				got = append(got, int16(s))
+			}
+		}
+
+		if len(got) != nSamples {
+			t.Fatalf("decoded %d samples, want %d", len(got), nSamples)
+		}
+		for i, s := range got {
+			if s != samples[i] {
+				t.Fatalf("sample %d = %d, want %d", i, s, samples[i])
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out reading encoded flac stream (deadlock?)")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build mp3
+
+package record
+
+import (
+	"io"
+	"os"
+
+	lame "github.com/viert/go-lame"
+)
+
+func recordMP3(r io.Reader, f *os.File, sampleRate, channels int) error {
+	enc, err := lame.NewEncoder(f)
+	if err != nil {
+		return err
+	}
+	enc.SetNumChannels(channels)
+	enc.SetInSamplerate(sampleRate)
+	enc.SetVBR(lame.VBR_DEFAULT)
+
+	if _, err := io.Copy(enc, r); err != nil {
+		enc.Close()
+		return err
+	}
+	return enc.Close()
+}
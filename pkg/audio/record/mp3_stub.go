@@ -0,0 +1,18 @@
+//go:build !mp3
+
+package record
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// recordMP3 is stubbed out by default: github.com/viert/go-lame is a cgo
+// binding onto libmp3lame and would otherwise make it a system dependency
+// of the base build for everyone, whether or not they ever pass
+// --record *.mp3. Build with -tags mp3 (and libmp3lame-dev installed) to
+// get the real encoder in mp3.go.
+func recordMP3(r io.Reader, f *os.File, sampleRate, channels int) error {
+	return fmt.Errorf("MP3 recording requires building with -tags mp3 (and libmp3lame-dev installed)")
+}
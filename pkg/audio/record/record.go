@@ -0,0 +1,137 @@
+// Package record tees the audio handed to client.Stream into a file on
+// disk, so a live session can be transcribed and archived at the same
+// time.
+package record
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+
+	"github.com/giulianopz/go-gsst/pkg/audio/flacenc"
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+// Format selects the on-disk encoding for a recording.
+type Format string
+
+const (
+	WAV  Format = "wav"
+	FLAC Format = "flac"
+	MP3  Format = "mp3"
+)
+
+// FormatFromPath derives a Format from path's extension, defaulting to WAV
+// when the extension is missing or unrecognized.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		return FLAC
+	case ".mp3":
+		return MP3
+	default:
+		return WAV
+	}
+}
+
+// Tee duplicates the PCM audio read from r into path, encoded as format,
+// and returns a reader that yields the same bytes as r so the caller can
+// keep streaming them to client.Stream unmodified. Recording happens in
+// a background goroutine and is finalized when r reaches EOF; the
+// returned reader reports that EOF only once the file is finalized.
+func Tee(r io.Reader, path string, format Format, sampleRate, channels int) (io.Reader, error) {
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %q: %w", path, err)
+	}
+
+	pr, pw := io.Pipe()
+	tee := io.TeeReader(r, pw)
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		var err error
+		switch format {
+		case FLAC:
+			err = recordFLAC(pr, f, sampleRate, channels)
+		case MP3:
+			err = recordMP3(pr, f, sampleRate, channels)
+		default:
+			err = recordWAV(pr, f, sampleRate, channels)
+		}
+		if err != nil {
+			logger.Error("cannot finalize recording", "path", path, "err", err)
+		}
+		pr.CloseWithError(err)
+		f.Close()
+	}()
+
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := tee.Read(p)
+		if err != nil {
+			// Pass the end of r on to the recorder and wait for it to
+			// finalize the file before reporting it to the caller.
+			pw.CloseWithError(err)
+			<-finished
+		}
+		return n, err
+	}), nil
+}
+
+// readerFunc adapts a plain function to io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+func recordWAV(r io.Reader, f *os.File, sampleRate, channels int) error {
+	enc := wav.NewEncoder(f, sampleRate, 16, channels, 1)
+
+	buf := &audio.IntBuffer{
+		Format:         &audio.Format{SampleRate: sampleRate, NumChannels: channels},
+		SourceBitDepth: 16,
+	}
+
+	raw := make([]byte, 4096)
+	for {
+		n, err := r.Read(raw)
+		if n > 0 {
+			buf.Data = pcmToInts(raw[:n])
+			if werr := enc.Write(buf); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
+
+func recordFLAC(r io.Reader, f *os.File, sampleRate, channels int) error {
+	enc, err := flacenc.NewEncoder(r, sampleRate, channels)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, enc)
+	return err
+}
+
+func pcmToInts(raw []byte) []int {
+	out := make([]int, len(raw)/2)
+	for i := range out {
+		lo, hi := raw[2*i], raw[2*i+1]
+		out[i] = int(int16(uint16(lo) | uint16(hi)<<8))
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+// Package transcode turns whatever audio container/codec a user hands us
+// on --file into the 16-bit PCM FLAC stream client.Stream expects,
+// shelling out to ffmpeg when it's available and falling back to a
+// pure-Go WAV decoder otherwise.
+package transcode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-audio/wav"
+
+	"github.com/giulianopz/go-gsst/pkg/audio/flacenc"
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+// Format identifies the container/codec detected for an input file.
+type Format string
+
+const (
+	FLAC    Format = "flac"
+	WAV     Format = "wav"
+	MP3     Format = "mp3"
+	Ogg     Format = "ogg"
+	Opus    Format = "opus"
+	MP4     Format = "mp4"
+	Unknown Format = "unknown"
+)
+
+// magic holds the byte sequences used to sniff a format, in priority order.
+var magic = []struct {
+	format Format
+	prefix []byte
+	offset int
+}{
+	{FLAC, []byte("fLaC"), 0},
+	{WAV, []byte("RIFF"), 0},
+	{Ogg, []byte("OggS"), 0},
+	{MP4, []byte("ftyp"), 4},
+	{MP3, []byte("ID3"), 0},
+	{MP3, []byte{0xFF, 0xFB}, 0},
+}
+
+// Detect sniffs the format of path from its magic bytes, falling back to
+// its file extension when the content is inconclusive.
+func Detect(path string) (Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Unknown, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 16)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return Unknown, err
+	}
+	head = head[:n]
+
+	for _, m := range magic {
+		end := m.offset + len(m.prefix)
+		if end <= len(head) && bytes.Equal(head[m.offset:end], m.prefix) {
+			return m.format, nil
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		return FLAC, nil
+	case ".wav", ".wave":
+		return WAV, nil
+	case ".mp3":
+		return MP3, nil
+	case ".ogg":
+		return Ogg, nil
+	case ".opus":
+		return Opus, nil
+	case ".mp4", ".m4a":
+		return MP4, nil
+	default:
+		return Unknown, nil
+	}
+}
+
+// ToFLAC returns a reader of FLAC-encoded audio for path, along with the
+// sample rate it was encoded at. sampleRate and channels, when non-zero,
+// force the output format. When zero, ffmpeg output defaults to 16000 Hz
+// mono; only a WAV file decoded without ffmpeg keeps the source's rate
+// and channel count.
+func ToFLAC(path string, sampleRate, channels int) (io.ReadCloser, int, error) {
+
+	format, err := Detect(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot detect format of %q: %w", path, err)
+	}
+
+	if format == WAV {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			return wavToFLAC(path, sampleRate, channels)
+		}
+	}
+
+	if sampleRate == 0 {
+		sampleRate = 16000
+	}
+	if channels == 0 {
+		channels = 1
+	}
+
+	return ffmpegToFLAC(path, sampleRate, channels)
+}
+
+// ffmpegToFLAC spawns `ffmpeg -i <path> -f flac -ac <channels> -ar
+// <sampleRate> pipe:1` and streams its stdout back to the caller.
+func ffmpegToFLAC(path string, sampleRate, channels int) (io.ReadCloser, int, error) {
+
+	cmd := exec.Command("ffmpeg",
+		"-i", path,
+		"-f", "flac",
+		"-ac", fmt.Sprint(channels),
+		"-ar", fmt.Sprint(sampleRate),
+		"pipe:1",
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot attach to ffmpeg stdout: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot attach to ffmpeg stderr: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, 0, fmt.Errorf("cannot start ffmpeg: %w", err)
+	}
+
+	go logStderr(stderr)
+
+	return &cmdReadCloser{ReadCloser: stdout, cmd: cmd}, sampleRate, nil
+}
+
+// wavToFLAC decodes path with the pure-Go WAV decoder and re-encodes it to
+// FLAC, for hosts without ffmpeg installed. Unlike ffmpegToFLAC, it cannot
+// resample or downmix, so a forced sampleRate/channels that doesn't match
+// the source is rejected rather than silently ignored; install ffmpeg to
+// force those parameters.
+func wavToFLAC(path string, wantSampleRate, wantChannels int) (io.ReadCloser, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dec := wav.NewDecoder(f)
+	if !dec.IsValidFile() {
+		f.Close()
+		return nil, 0, fmt.Errorf("%q is not a valid WAV file", path)
+	}
+
+	buf, err := dec.FullPCMBuffer()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("cannot decode wav: %w", err)
+	}
+	f.Close()
+
+	sampleRate := buf.Format.SampleRate
+	channels := buf.Format.NumChannels
+
+	if wantSampleRate != 0 && wantSampleRate != sampleRate {
+		return nil, 0, fmt.Errorf("%q is %dHz; forcing --target-sample-rate %d requires ffmpeg", path, sampleRate, wantSampleRate)
+	}
+	if wantChannels != 0 && wantChannels != channels {
+		return nil, 0, fmt.Errorf("%q has %d channel(s); forcing --channels %d requires ffmpeg", path, channels, wantChannels)
+	}
+
+	if buf.SourceBitDepth != 16 {
+		return nil, 0, fmt.Errorf("%q is %d-bit PCM; the no-ffmpeg fallback only supports 16-bit WAV, install ffmpeg to transcode it", path, buf.SourceBitDepth)
+	}
+
+	pcm := new(bytes.Buffer)
+	for _, s := range buf.Data {
+		pcm.WriteByte(byte(s))
+		pcm.WriteByte(byte(s >> 8))
+	}
+
+	enc, err := flacenc.NewEncoder(pcm, sampleRate, channels)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot encode flac: %w", err)
+	}
+
+	return io.NopCloser(enc), sampleRate, nil
+}
+
+func logStderr(r io.Reader) {
+	b, _ := io.ReadAll(r)
+	if len(b) > 0 {
+		logger.Debug("ffmpeg", "stderr", string(b))
+	}
+}
+
+// cmdReadCloser waits on the underlying ffmpeg process once its stdout
+// pipe is closed, surfacing any non-zero exit as an error.
+type cmdReadCloser struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *cmdReadCloser) Close() error {
+	closeErr := c.ReadCloser.Close()
+	if err := c.cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg: %w", err)
+	}
+	return closeErr
+}
@@ -0,0 +1,47 @@
+package transcode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTemp(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("cannot write temp file: %v", err)
+	}
+	return path
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+		want    Format
+	}{
+		{"flac magic", "audio.bin", []byte("fLaC\x00\x00\x00\x22"), FLAC},
+		{"wav magic", "audio.bin", []byte("RIFF....WAVEfmt "), WAV},
+		{"ogg magic", "audio.bin", []byte("OggS\x00\x02"), Ogg},
+		{"mp4 ftyp box", "audio.bin", []byte("\x00\x00\x00\x18ftypisom"), MP4},
+		{"id3 mp3", "audio.bin", []byte("ID3\x03\x00\x00\x00"), MP3},
+		{"frame-sync mp3", "audio.bin", []byte{0xFF, 0xFB, 0x90, 0x00}, MP3},
+		{"extension fallback when content is inconclusive", "audio.opus", []byte{0, 0, 0, 0}, Opus},
+		{"unknown content and extension", "audio.xyz", []byte{0, 0, 0, 0}, Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, tt.file, tt.content)
+			got, err := Detect(path)
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
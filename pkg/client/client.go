@@ -0,0 +1,173 @@
+// Package client implements the unofficial "full-duplex" speech recognition
+// protocol used by Chromium's built-in dictation feature.
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/giulianopz/go-gsst/pkg/opts"
+)
+
+const (
+	baseURL = "https://www.google.com/speech-api/full-duplex/v1"
+)
+
+// Client talks to the Chrome full-duplex speech-api endpoint.
+type Client struct {
+	httpClient *http.Client
+}
+
+// New returns a Client ready to use.
+func New() *Client {
+	return &Client{httpClient: &http.Client{}}
+}
+
+// Stream uploads the audio read from r, encoded at sampleRate, reporting
+// every transcription event to handler as it arrives.
+func (c *Client) Stream(ctx context.Context, r io.Reader, sampleRate int, o *opts.Options, handler ResultHandler) error {
+
+	pair, err := newPair()
+	if err != nil {
+		return fmt.Errorf("cannot generate pair id: %w", err)
+	}
+
+	outputParam := "pb"
+	if o.Output == opts.Text {
+		outputParam = "json"
+	}
+
+	downURL := fmt.Sprintf("%s/down?pair=%s&output=%s", baseURL, pair, outputParam)
+	downReq, err := http.NewRequestWithContext(ctx, http.MethodGet, downURL, nil)
+	if err != nil {
+		return fmt.Errorf("cannot build down request: %w", err)
+	}
+	downReq.Header.Set("User-Agent", o.UserAgent)
+
+	downResp, err := c.httpClient.Do(downReq)
+	if err != nil {
+		return fmt.Errorf("cannot open down stream: %w", err)
+	}
+	defer downResp.Body.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := readResults(downResp.Body, o, handler); err != nil {
+			handler.OnError(fmt.Errorf("cannot read results: %w", err))
+		}
+	}()
+
+	upValues := url.Values{}
+	upValues.Set("key", o.ApiKey)
+	upValues.Set("pair", pair)
+	upValues.Set("lang", o.Language)
+	upValues.Set("output", outputParam)
+	upValues.Set("maxAlternatives", strconv.Itoa(o.MaxAlts))
+	upValues.Set("pFilter", strconv.Itoa(o.ProfanityFilter))
+	upValues.Set("continuous", strconv.FormatBool(o.Continuous))
+	upValues.Set("interim", strconv.FormatBool(o.Interim))
+
+	upURL := fmt.Sprintf("%s/up?%s", baseURL, upValues.Encode())
+	upReq, err := http.NewRequestWithContext(ctx, http.MethodPost, upURL, r)
+	if err != nil {
+		return fmt.Errorf("cannot build up request: %w", err)
+	}
+	upReq.Header.Set("User-Agent", o.UserAgent)
+	upReq.Header.Set("Content-Type", fmt.Sprintf("audio/x-flac; rate=%d", sampleRate))
+
+	upResp, err := c.httpClient.Do(upReq)
+	if err != nil {
+		return fmt.Errorf("cannot send up stream: %w", err)
+	}
+	defer upResp.Body.Close()
+
+	if upResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("up stream returned status %s", upResp.Status)
+	}
+
+	<-done
+	return nil
+}
+
+// downChunk mirrors the JSON schema of a single down-stream message.
+type downChunk struct {
+	Result []struct {
+		Alternative []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+		} `json:"alternative"`
+		Final     bool    `json:"final"`
+		Stability float64 `json:"stability"`
+	} `json:"result"`
+}
+
+// readResults reads the down-stream body, which is a sequence of chunks
+// each prefixed by a 4-byte big-endian length, and reports every result
+// found in it to handler.
+func readResults(r io.Reader, o *opts.Options, handler ResultHandler) error {
+	lenBuf := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, lenBuf); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		n := binary.BigEndian.Uint32(lenBuf)
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return err
+		}
+
+		if o.Output != opts.Text {
+			// The binary wire format is Google's undocumented protobuf;
+			// hand it to the handler verbatim rather than guessing at a
+			// schema for it.
+			handler.OnFinal(Result{Raw: chunk, Language: o.Language})
+			continue
+		}
+
+		var parsed downChunk
+		if err := json.Unmarshal(chunk, &parsed); err != nil {
+			handler.OnError(fmt.Errorf("cannot parse result chunk: %w", err))
+			continue
+		}
+
+		for _, res := range parsed.Result {
+			result := Result{
+				Final:     res.Final,
+				Stability: res.Stability,
+				Language:  o.Language,
+			}
+			for _, alt := range res.Alternative {
+				result.Alternatives = append(result.Alternatives, Alternative{
+					Transcript: alt.Transcript,
+					Confidence: alt.Confidence,
+				})
+			}
+			if result.Final {
+				handler.OnFinal(result)
+			} else {
+				handler.OnInterim(result)
+			}
+		}
+	}
+}
+
+func newPair() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
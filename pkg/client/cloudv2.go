@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	speech "cloud.google.com/go/speech/apiv2"
+	speechpb "cloud.google.com/go/speech/apiv2/speechpb"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/giulianopz/go-gsst/pkg/logger"
+	"github.com/giulianopz/go-gsst/pkg/opts"
+)
+
+// CloudV2Client talks to the official Google Cloud Speech-to-Text v2 API
+// over its bidirectional streaming gRPC endpoint.
+type CloudV2Client struct {
+	grpc       *speech.Client
+	project    string
+	location   string
+	recognizer string
+}
+
+// NewCloudV2 dials Speech-to-Text v2 and returns a client bound to the given
+// project, location and recognizer (e.g. "projects/p/locations/global/recognizers/r").
+func NewCloudV2(ctx context.Context, project, location, recognizer string) (*CloudV2Client, error) {
+	grpcClient, err := speech.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot dial speech-to-text v2: %w", err)
+	}
+	return &CloudV2Client{
+		grpc:       grpcClient,
+		project:    project,
+		location:   location,
+		recognizer: recognizer,
+	}, nil
+}
+
+// Stream uploads the audio read from r, encoded at sampleRate, reporting
+// every transcription event to handler as it arrives. It mirrors the
+// contract of Client.Stream so the two backends are interchangeable from
+// the CLI's point of view.
+func (c *CloudV2Client) Stream(ctx context.Context, r io.Reader, sampleRate int, o *opts.Options, handler ResultHandler) error {
+
+	stream, err := c.grpc.StreamingRecognize(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot open streaming recognize call: %w", err)
+	}
+
+	recognizerName := c.recognizer
+	if recognizerName == "" {
+		recognizerName = fmt.Sprintf("projects/%s/locations/%s/recognizers/_", c.project, c.location)
+	}
+
+	initial := &speechpb.StreamingRecognizeRequest{
+		Recognizer: recognizerName,
+		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
+			StreamingConfig: &speechpb.StreamingRecognitionConfig{
+				Config: &speechpb.RecognitionConfig{
+					// Every caller feeds this backend FLAC-container bytes (the
+					// same encoder output used for the Chrome backend), so let the
+					// server sniff the container instead of declaring a raw PCM
+					// encoding it doesn't actually contain.
+					DecodingConfig: &speechpb.RecognitionConfig_AutoDecodingConfig{
+						AutoDecodingConfig: &speechpb.AutoDetectDecodingConfig{},
+					},
+					LanguageCodes: []string{cloudV2Language(o.Language)},
+					Model:         "long",
+				},
+				StreamingFeatures: &speechpb.StreamingRecognitionFeatures{
+					InterimResults:            o.Interim,
+					EnableVoiceActivityEvents: true,
+				},
+			},
+		},
+	}
+	if err := stream.Send(initial); err != nil {
+		return fmt.Errorf("cannot send streaming config: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				done <- nil
+				return
+			}
+			if err != nil {
+				done <- err
+				return
+			}
+			reportCloudV2Response(resp, o, handler)
+		}
+	}()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&speechpb.StreamingRecognizeRequest{
+				StreamingRequest: &speechpb.StreamingRecognizeRequest_Audio{
+					Audio: append([]byte(nil), buf[:n]...),
+				},
+			}); sendErr != nil {
+				return fmt.Errorf("cannot send audio content: %w", sendErr)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read audio: %w", err)
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		logger.Error("cannot close send side of stream", "err", err)
+	}
+
+	return <-done
+}
+
+func reportCloudV2Response(resp *speechpb.StreamingRecognizeResponse, o *opts.Options, handler ResultHandler) {
+
+	if evtType := resp.GetSpeechEventType(); evtType != speechpb.StreamingRecognizeResponse_SPEECH_EVENT_TYPE_UNSPECIFIED {
+		handler.OnEndpoint(VoiceActivityEvent{Type: evtType.String()})
+	}
+
+	var raw []byte
+	if o.Output != opts.Text {
+		b, err := proto.Marshal(resp)
+		if err != nil {
+			handler.OnError(fmt.Errorf("cannot marshal response: %w", err))
+		}
+		raw = b
+	}
+
+	for _, res := range resp.GetResults() {
+		result := Result{
+			Final:     res.GetIsFinal(),
+			Stability: float64(res.GetStability()),
+			Language:  res.GetLanguageCode(),
+			Raw:       raw,
+		}
+		for _, alt := range res.GetAlternatives() {
+			result.Alternatives = append(result.Alternatives, Alternative{
+				Transcript: alt.GetTranscript(),
+				Confidence: float64(alt.GetConfidence()),
+			})
+		}
+		if result.Final {
+			handler.OnFinal(result)
+		} else {
+			handler.OnInterim(result)
+		}
+	}
+}
+
+// cloudV2Language translates opts.Apply's default Language, "null" (the
+// Chrome endpoint's sentinel for auto-detection), into the language code
+// Speech-to-Text v2 uses for the same thing. v2 rejects "null" outright,
+// so every --backend cloud-v2 call without an explicit --language would
+// otherwise fail.
+func cloudV2Language(lang string) string {
+	if lang == "null" {
+		return "auto"
+	}
+	return lang
+}
@@ -0,0 +1,22 @@
+package client
+
+import "testing"
+
+func TestCloudV2Language(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		want string
+	}{
+		{"chrome auto-detect sentinel maps to cloud-v2's", "null", "auto"},
+		{"explicit language code passes through", "en-US", "en-US"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cloudV2Language(tt.lang); got != tt.want {
+				t.Errorf("cloudV2Language(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
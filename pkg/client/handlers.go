@@ -0,0 +1,49 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+// StdoutJSON is a ResultHandler that reproduces the CLI's historical
+// behavior for `--output json`: one transcription result per line.
+type StdoutJSON struct{}
+
+func (StdoutJSON) OnInterim(r Result) { printJSON(r) }
+func (StdoutJSON) OnFinal(r Result)   { printJSON(r) }
+
+func (StdoutJSON) OnEndpoint(e VoiceActivityEvent) {
+	logger.Debug("voice activity event", "type", e.Type)
+}
+
+func (StdoutJSON) OnError(err error) {
+	logger.Error("stream error", "err", err)
+}
+
+func printJSON(r Result) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		logger.Error("cannot marshal result", "err", err)
+		return
+	}
+	fmt.Println(string(b))
+}
+
+// StdoutProtobuf is a ResultHandler that reproduces the CLI's historical
+// behavior for `--output pb`: the raw bytes received from the backend,
+// written straight to stdout.
+type StdoutProtobuf struct{}
+
+func (StdoutProtobuf) OnInterim(r Result) { os.Stdout.Write(r.Raw) }
+func (StdoutProtobuf) OnFinal(r Result)   { os.Stdout.Write(r.Raw) }
+
+func (StdoutProtobuf) OnEndpoint(e VoiceActivityEvent) {
+	logger.Debug("voice activity event", "type", e.Type)
+}
+
+func (StdoutProtobuf) OnError(err error) {
+	logger.Error("stream error", "err", err)
+}
@@ -0,0 +1,37 @@
+package client
+
+// Alternative is one possible transcription of an utterance.
+type Alternative struct {
+	Transcript string
+	Confidence float64
+}
+
+// Result is a single transcription event reported by a backend, either
+// interim (still being refined) or final.
+type Result struct {
+	Alternatives []Alternative
+	Final        bool
+	Stability    float64
+	Language     string
+
+	// Raw holds the verbatim bytes received from the backend, populated
+	// when the caller asked for binary output and the message can't be
+	// losslessly reduced to the fields above.
+	Raw []byte
+}
+
+// VoiceActivityEvent reports a voice-activity boundary detected by a
+// backend (e.g. speech start/end), used to drive endpointing.
+type VoiceActivityEvent struct {
+	Type string
+}
+
+// ResultHandler reacts to the events produced while a backend streams
+// transcription results. Implementations must be safe to call from the
+// goroutine draining the backend's response stream.
+type ResultHandler interface {
+	OnInterim(Result)
+	OnFinal(Result)
+	OnEndpoint(VoiceActivityEvent)
+	OnError(error)
+}
@@ -0,0 +1,37 @@
+// Package logger provides a process-wide structured logger built on log/slog.
+package logger
+
+import (
+	"log/slog"
+	"os"
+)
+
+var (
+	levelVar = new(slog.LevelVar)
+	log      = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: levelVar}))
+)
+
+// Level sets the minimum level logged from this point on.
+func Level(lvl slog.Level) {
+	levelVar.Set(lvl)
+}
+
+// Debug logs at debug level.
+func Debug(msg string, args ...any) {
+	log.Debug(msg, args...)
+}
+
+// Info logs at info level.
+func Info(msg string, args ...any) {
+	log.Info(msg, args...)
+}
+
+// Warn logs at warn level.
+func Warn(msg string, args ...any) {
+	log.Warn(msg, args...)
+}
+
+// Error logs at error level.
+func Error(msg string, args ...any) {
+	log.Error(msg, args...)
+}
@@ -0,0 +1,114 @@
+// Package mic captures PCM audio from the default input device using
+// malgo (miniaudio bindings) and exposes it as a plain io.Reader.
+package mic
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gen2brain/malgo"
+
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+// frameDuration is the size of a single capture frame. 20ms is the frame
+// size expected further down the pipeline (FLAC encoder, VAD).
+const frameDuration = 20 // milliseconds
+
+// ringFrames bounds how many frames Capture buffers before the mic
+// callback blocks, i.e. how much audio we can absorb under backpressure
+// before we'd have to start dropping it.
+const ringFrames = 50 // ~1s at 20ms frames
+
+// Capture reads PCM frames off the default input device.
+type Capture struct {
+	ctx    *malgo.AllocatedContext
+	device *malgo.Device
+	frames chan []byte
+	closed chan struct{}
+
+	pending []byte
+}
+
+// Open starts capturing 16-bit PCM audio at sampleRate with the given
+// channel count from the default input device.
+func Open(sampleRate, channels uint32) (*Capture, error) {
+
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, func(msg string) { logger.Debug("malgo", "msg", msg) })
+	if err != nil {
+		return nil, err
+	}
+
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
+	deviceConfig.Capture.Format = malgo.FormatS16
+	deviceConfig.Capture.Channels = channels
+	deviceConfig.SampleRate = sampleRate
+	deviceConfig.PeriodSizeInMilliseconds = frameDuration
+
+	c := &Capture{
+		ctx:    ctx,
+		frames: make(chan []byte, ringFrames),
+		closed: make(chan struct{}),
+	}
+
+	onRecvFrames := func(_, pSamples []byte, framecount uint32) {
+		frame := make([]byte, len(pSamples))
+		copy(frame, pSamples)
+
+		select {
+		case c.frames <- frame:
+		case <-c.closed:
+		}
+	}
+
+	device, err := malgo.InitDevice(ctx.Context, deviceConfig, malgo.DeviceCallbacks{
+		Data: onRecvFrames,
+	})
+	if err != nil {
+		ctx.Free()
+		return nil, err
+	}
+	c.device = device
+
+	if err := device.Start(); err != nil {
+		device.Uninit()
+		ctx.Free()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Read implements io.Reader, draining captured PCM frames in order.
+func (c *Capture) Read(p []byte) (int, error) {
+	if len(c.pending) == 0 {
+		select {
+		case frame, ok := <-c.frames:
+			if !ok {
+				return 0, io.EOF
+			}
+			c.pending = frame
+		case <-c.closed:
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// Close stops capturing and releases the underlying device and context.
+func (c *Capture) Close() error {
+	select {
+	case <-c.closed:
+		return errors.New("mic: already closed")
+	default:
+		close(c.closed)
+	}
+
+	c.device.Uninit()
+	c.ctx.Free()
+	close(c.frames)
+	return nil
+}
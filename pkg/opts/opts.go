@@ -0,0 +1,183 @@
+// Package opts collects the options accepted by pkg/client into a single,
+// functional-options-configurable struct.
+package opts
+
+import "time"
+
+const (
+	// DefaultUserAgent is sent when none is given on the command line.
+	DefaultUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36"
+
+	// DefaultSampleRate is used for raw microphone input, which is assumed
+	// to be 16-bit PCM at 16 kHz unless told otherwise.
+	DefaultSampleRate = 16000
+
+	// DefaultVADSensitivity is a middle-of-the-road aggressiveness for the
+	// WebRTC VAD (0-3, least to most aggressive).
+	DefaultVADSensitivity = 2
+
+	// DefaultVADMinSilence is how much trailing silence ends an utterance.
+	DefaultVADMinSilence = 800 * time.Millisecond
+
+	// DefaultVADMaxUtterance keeps utterances comfortably under backends'
+	// ~5-minute streaming cap before forcing a resume.
+	DefaultVADMaxUtterance = 4 * time.Minute
+)
+
+// BackendKind selects which speech recognition service client.Stream talks to.
+type BackendKind string
+
+const (
+	// Chrome is the unofficial Chrome-key full-duplex endpoint.
+	Chrome BackendKind = "chrome"
+	// CloudV2 is the official Google Cloud Speech-to-Text v2 gRPC API.
+	CloudV2 BackendKind = "cloud-v2"
+)
+
+// OutputFormat selects how transcription results are rendered.
+type OutputFormat int
+
+const (
+	// Binary renders results as the raw protobuf message received from the server.
+	Binary OutputFormat = iota
+	// Text renders results as JSON.
+	Text
+)
+
+// Options holds every knob accepted by client.Stream.
+type Options struct {
+	Verbose         bool
+	FilePath        string
+	ApiKey          string
+	Output          OutputFormat
+	Language        string
+	Continuous      bool
+	Interim         bool
+	MaxAlts         int
+	ProfanityFilter int
+	UserAgent       string
+
+	Backend    BackendKind
+	Project    string
+	Location   string
+	Recognizer string
+
+	InputFormat      string
+	TargetSampleRate int
+	Channels         int
+
+	RecordPath   string
+	RecordFormat string
+
+	VADEnabled      bool
+	VADSensitivity  int
+	VADMinSilence   time.Duration
+	VADMaxUtterance time.Duration
+}
+
+// Option mutates an Options value.
+type Option func(*Options)
+
+// Apply builds an Options value out of the given Option list.
+func Apply(opts ...Option) *Options {
+	o := &Options{
+		Language:  "null",
+		MaxAlts:   1,
+		UserAgent: DefaultUserAgent,
+		Backend:   Chrome,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func Verbose(v bool) Option {
+	return func(o *Options) { o.Verbose = v }
+}
+
+func FilePath(path string) Option {
+	return func(o *Options) { o.FilePath = path }
+}
+
+func ApiKey(key string) Option {
+	return func(o *Options) { o.ApiKey = key }
+}
+
+func Output(f OutputFormat) Option {
+	return func(o *Options) { o.Output = f }
+}
+
+func Language(lang string) Option {
+	return func(o *Options) { o.Language = lang }
+}
+
+func Continuous(c bool) Option {
+	return func(o *Options) { o.Continuous = c }
+}
+
+func Interim(i bool) Option {
+	return func(o *Options) { o.Interim = i }
+}
+
+func MaxAlts(n int) Option {
+	return func(o *Options) { o.MaxAlts = n }
+}
+
+func ProfanityFilter(n int) Option {
+	return func(o *Options) { o.ProfanityFilter = n }
+}
+
+func UserAgent(ua string) Option {
+	return func(o *Options) { o.UserAgent = ua }
+}
+
+func Backend(b BackendKind) Option {
+	return func(o *Options) { o.Backend = b }
+}
+
+func Project(p string) Option {
+	return func(o *Options) { o.Project = p }
+}
+
+func Location(l string) Option {
+	return func(o *Options) { o.Location = l }
+}
+
+func Recognizer(r string) Option {
+	return func(o *Options) { o.Recognizer = r }
+}
+
+func InputFormat(f string) Option {
+	return func(o *Options) { o.InputFormat = f }
+}
+
+func TargetSampleRate(rate int) Option {
+	return func(o *Options) { o.TargetSampleRate = rate }
+}
+
+func Channels(n int) Option {
+	return func(o *Options) { o.Channels = n }
+}
+
+// RecordTo duplicates the streamed audio into path, encoded as format
+// ('wav', 'flac' or 'mp3').
+func RecordTo(path, format string) Option {
+	return func(o *Options) {
+		o.RecordPath = path
+		o.RecordFormat = format
+	}
+}
+
+// VAD enables voice-activity based segmentation of --mic audio, ending an
+// utterance after minSilence of trailing silence and forcing a resume
+// after maxUtterance of total audio. sensitivity ranges 0 (least
+// aggressive) to 3 (most aggressive).
+func VAD(sensitivity int, minSilence, maxUtterance time.Duration) Option {
+	return func(o *Options) {
+		o.VADEnabled = true
+		o.VADSensitivity = sensitivity
+		o.VADMinSilence = minSilence
+		o.VADMaxUtterance = maxUtterance
+	}
+}
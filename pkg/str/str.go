@@ -0,0 +1,10 @@
+// Package str holds small string helpers shared by the rest of the codebase.
+package str
+
+// GetOrDefault returns s if it isn't empty, otherwise def.
+func GetOrDefault(s, def string) string {
+	if s != "" {
+		return s
+	}
+	return def
+}
@@ -0,0 +1,106 @@
+package vad
+
+import "time"
+
+// Boundary reports why Segmenter.Feed ended the current utterance.
+type Boundary int
+
+const (
+	// None means the frame just fed didn't cross any boundary.
+	None Boundary = iota
+	// Utterance means enough trailing silence was seen to end the
+	// current utterance.
+	Utterance
+	// MaxDuration means the current utterance is approaching the
+	// backend's streaming duration cap and should be resumed on a new
+	// upstream connection.
+	MaxDuration
+)
+
+// tailFrames is how many trailing frames Segmenter keeps so a new
+// upstream connection can be seeded with the audio right before a
+// MaxDuration cut, avoiding clipped words.
+const tailFrames = 10 // ~200ms at 20ms frames
+
+// Segmenter turns a sequence of fixed-size PCM frames into utterance
+// boundaries, using a Detector to tell speech from silence.
+type Segmenter struct {
+	detector     Detector
+	frameDur     time.Duration
+	minSilence   time.Duration
+	maxUtterance time.Duration
+
+	silence        time.Duration
+	elapsed        time.Duration
+	endedUtterance bool
+	tail           [][]byte
+}
+
+// NewSegmenter returns a Segmenter that classifies frameDur-long frames
+// with detector, ending an utterance after minSilence of trailing
+// silence or forcing a resume after maxUtterance of total audio.
+func NewSegmenter(detector Detector, frameDur, minSilence, maxUtterance time.Duration) *Segmenter {
+	return &Segmenter{
+		detector:     detector,
+		frameDur:     frameDur,
+		minSilence:   minSilence,
+		maxUtterance: maxUtterance,
+	}
+}
+
+// Feed classifies frame and returns the boundary it crosses, if any.
+// Feed always buffers frame into the tail kept for Resume, regardless of
+// the boundary returned.
+func (s *Segmenter) Feed(frame []byte) (Boundary, error) {
+	s.elapsed += s.frameDur
+	s.pushTail(frame)
+
+	speech, err := s.detector.IsSpeech(frame)
+	if err != nil {
+		return None, err
+	}
+
+	if speech {
+		s.silence = 0
+		s.endedUtterance = false
+	} else {
+		s.silence += s.frameDur
+	}
+
+	switch {
+	case s.elapsed >= s.maxUtterance:
+		s.elapsed = 0
+		s.silence = 0
+		s.endedUtterance = false
+		return MaxDuration, nil
+	case !speech && !s.endedUtterance && s.silence >= s.minSilence:
+		// Trailing silence just crossed minSilence; endedUtterance guards
+		// against refiring every subsequent silent frame.
+		s.elapsed = 0
+		s.endedUtterance = true
+		return Utterance, nil
+	default:
+		return None, nil
+	}
+}
+
+// Resume returns the trailing audio buffered before the last boundary,
+// to be replayed at the start of a new upstream connection so no word
+// spanning the cut is lost.
+func (s *Segmenter) Resume() []byte {
+	var out []byte
+	for _, f := range s.tail {
+		out = append(out, f...)
+	}
+	return out
+}
+
+func (s *Segmenter) pushTail(frame []byte) {
+	cp := make([]byte, len(frame))
+	copy(cp, frame)
+
+	s.tail = append(s.tail, cp)
+	if len(s.tail) > tailFrames {
+		s.tail = s.tail[1:]
+	}
+}
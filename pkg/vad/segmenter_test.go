@@ -0,0 +1,75 @@
+package vad
+
+import (
+	"testing"
+	"time"
+)
+
+// scriptedDetector returns the next value of speech on each call to
+// IsSpeech, ignoring the frame's contents.
+type scriptedDetector struct {
+	speech []bool
+	i      int
+}
+
+func (d *scriptedDetector) IsSpeech(frame []byte) (bool, error) {
+	v := d.speech[d.i]
+	d.i++
+	return v, nil
+}
+
+func TestSegmenterFeedUtteranceBoundary(t *testing.T) {
+	det := &scriptedDetector{speech: []bool{true, true, false, false, false}}
+	s := NewSegmenter(det, 20*time.Millisecond, 40*time.Millisecond, time.Hour)
+
+	var got []Boundary
+	for range det.speech {
+		b, err := s.Feed(make([]byte, 2))
+		if err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+		got = append(got, b)
+	}
+
+	want := []Boundary{None, None, None, Utterance, None}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("frame %d: Feed() = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSegmenterFeedMaxDuration(t *testing.T) {
+	det := &scriptedDetector{speech: []bool{true, true, true}}
+	s := NewSegmenter(det, 20*time.Millisecond, time.Hour, 40*time.Millisecond)
+
+	if b, _ := s.Feed(make([]byte, 2)); b != None {
+		t.Fatalf("frame 0: Feed() = %v, want None", b)
+	}
+	b, err := s.Feed(make([]byte, 2))
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	if b != MaxDuration {
+		t.Fatalf("frame 1: Feed() = %v, want MaxDuration", b)
+	}
+}
+
+func TestSegmenterResumeReturnsTrailingAudio(t *testing.T) {
+	det := &scriptedDetector{speech: []bool{true, true}}
+	s := NewSegmenter(det, 20*time.Millisecond, time.Hour, time.Hour)
+
+	s.Feed([]byte{0x01, 0x02})
+	s.Feed([]byte{0x03, 0x04})
+
+	want := []byte{0x01, 0x02, 0x03, 0x04}
+	got := s.Resume()
+	if len(got) != len(want) {
+		t.Fatalf("Resume() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Resume() = %v, want %v", got, want)
+		}
+	}
+}
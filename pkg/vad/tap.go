@@ -0,0 +1,82 @@
+package vad
+
+import (
+	"io"
+
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+// Tap wraps a PCM reader, classifying it frame-by-frame with a Segmenter.
+// Once a boundary is crossed, Tap finishes delivering the bytes already
+// read and then reports io.EOF, so whatever is consuming it (typically a
+// flacenc.Encoder feeding client.Stream) winds down its upload normally
+// instead of being torn down mid-request. It mirrors the tee pattern
+// pkg/audio/record uses to duplicate a stream without disturbing it.
+type Tap struct {
+	r         io.Reader
+	seg       *Segmenter
+	frameSize int
+
+	// Boundary is set once Read starts reporting io.EOF, recording which
+	// boundary caused it.
+	Boundary Boundary
+
+	buf  []byte
+	done bool
+}
+
+// NewTap returns a Tap that reads frameSize-byte PCM frames off r and
+// classifies each with seg.
+func NewTap(r io.Reader, seg *Segmenter, frameSize int) *Tap {
+	return &Tap{
+		r:         r,
+		seg:       seg,
+		frameSize: frameSize,
+	}
+}
+
+// Read implements io.Reader, classifying complete frames as they
+// accumulate and passing every byte read from the underlying reader
+// straight through. Once a frame crosses a boundary, Read finishes
+// returning the bytes already read off the underlying reader, then
+// reports io.EOF on every subsequent call.
+func (t *Tap) Read(p []byte) (int, error) {
+	if t.done {
+		return 0, io.EOF
+	}
+
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if boundary := t.classify(p[:n]); boundary != None {
+			t.done = true
+			t.Boundary = boundary
+		}
+	}
+	return n, err
+}
+
+func (t *Tap) classify(p []byte) Boundary {
+	t.buf = append(t.buf, p...)
+
+	crossed := None
+	for len(t.buf) >= t.frameSize {
+		frame := t.buf[:t.frameSize]
+		t.buf = t.buf[t.frameSize:]
+
+		boundary, err := t.seg.Feed(frame)
+		if err != nil {
+			logger.Error("cannot classify frame", "err", err)
+			continue
+		}
+		if boundary != None {
+			crossed = boundary
+		}
+	}
+	return crossed
+}
+
+// Resume returns the trailing audio buffered before the last boundary,
+// to be replayed at the start of a new upstream connection.
+func (t *Tap) Resume() []byte {
+	return t.seg.Resume()
+}
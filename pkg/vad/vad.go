@@ -0,0 +1,87 @@
+// Package vad classifies 20ms PCM frames as speech or silence, using
+// WebRTC's VAD when available and an energy-threshold heuristic
+// otherwise.
+package vad
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/maxhawkins/go-webrtcvad"
+
+	"github.com/giulianopz/go-gsst/pkg/logger"
+)
+
+// Detector classifies a single PCM frame as speech or silence.
+type Detector interface {
+	IsSpeech(frame []byte) (bool, error)
+}
+
+// NewWebRTC returns a Detector backed by libwebrtc's VAD. sensitivity
+// ranges 0 (least aggressive, i.e. more likely to call a frame speech)
+// to 3 (most aggressive).
+func NewWebRTC(sensitivity int, sampleRate int) (Detector, error) {
+	v, err := webrtcvad.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.SetMode(sensitivity); err != nil {
+		return nil, err
+	}
+	return &webrtcDetector{vad: v, sampleRate: sampleRate}, nil
+}
+
+type webrtcDetector struct {
+	vad        *webrtcvad.VAD
+	sampleRate int
+}
+
+func (d *webrtcDetector) IsSpeech(frame []byte) (bool, error) {
+	return d.vad.Process(d.sampleRate, frame)
+}
+
+// energyDetector is a dependency-free fallback that flags a frame as
+// speech when its RMS amplitude clears threshold. It's far less
+// accurate than WebRTC's VAD but requires no cgo or external libs.
+type energyDetector struct {
+	threshold float64
+}
+
+// NewEnergy returns a pure-Go energy-threshold Detector, for hosts where
+// the WebRTC VAD bindings can't be built.
+func NewEnergy(threshold float64) Detector {
+	return &energyDetector{threshold: threshold}
+}
+
+func (d *energyDetector) IsSpeech(frame []byte) (bool, error) {
+	if len(frame) < 2 {
+		return false, nil
+	}
+
+	var sumSquares float64
+	n := len(frame) / 2
+	for i := 0; i < n; i++ {
+		sample := int16(binary.LittleEndian.Uint16(frame[2*i : 2*i+2]))
+		sumSquares += float64(sample) * float64(sample)
+	}
+
+	rms := math.Sqrt(sumSquares / float64(n))
+	return rms > d.threshold, nil
+}
+
+// New returns the best available Detector for sensitivity (0-3) and
+// sampleRate, falling back to the energy heuristic if the WebRTC VAD
+// bindings fail to initialize (e.g. not built for this platform).
+func New(sensitivity int, sampleRate int) Detector {
+	d, err := NewWebRTC(sensitivity, sampleRate)
+	if err != nil {
+		logger.Warn("cannot init webrtc vad, falling back to energy detector", "err", err)
+		return NewEnergy(defaultEnergyThreshold)
+	}
+	return d
+}
+
+// defaultEnergyThreshold was picked empirically against 16-bit PCM
+// recorded at typical microphone gain; it errs toward under-detecting
+// silence rather than clipping the start of words.
+const defaultEnergyThreshold = 300
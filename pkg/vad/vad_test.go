@@ -0,0 +1,41 @@
+package vad
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func frameOf(samples ...int16) []byte {
+	buf := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(buf[2*i:2*i+2], uint16(s))
+	}
+	return buf
+}
+
+func TestEnergyDetectorIsSpeech(t *testing.T) {
+	d := NewEnergy(300)
+
+	tests := []struct {
+		name  string
+		frame []byte
+		want  bool
+	}{
+		{"empty frame", nil, false},
+		{"too short for a sample", []byte{0x01}, false},
+		{"silence below threshold", frameOf(10, -10, 5, -5), false},
+		{"loud frame above threshold", frameOf(5000, -5000, 4000, -4000), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.IsSpeech(tt.frame)
+			if err != nil {
+				t.Fatalf("IsSpeech() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsSpeech() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}